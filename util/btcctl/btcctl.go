@@ -0,0 +1,247 @@
+// Copyright (c) 2013-2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/conformal/btcjson"
+)
+
+const (
+	showHelpMessage = "Specify -h to show available options"
+	listCmdMessage  = "Specify -l to list available commands"
+)
+
+// unusableFlags are the command usage flags which this utility is not able
+// to use.  In particular it doesn't support websockets and consequently
+// notifications.
+const unusableFlags = btcjson.UFWebsocketOnly | btcjson.UFNotification
+
+// commandUsage display the usage for a specific command.
+func commandUsage(method string) {
+	usage, err := btcjson.MethodUsageText(method)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to obtain command usage:", err)
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, " ", usage)
+}
+
+// usage displays the general usage when the help flag is not displayed and
+// an invalid command was specified.  The commandUsage function is used
+// instead when a valid command was specified.
+func usage(errorMessage string) {
+	appName := filepath.Base(os.Args[0])
+	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+	fmt.Fprintln(os.Stderr, errorMessage)
+	fmt.Fprintln(os.Stderr, "Usage:", appName, "[OPTIONS] <command> <args...>")
+	fmt.Fprintln(os.Stderr, showHelpMessage)
+	fmt.Fprintln(os.Stderr, listCmdMessage)
+}
+
+// listCommands categorizes and lists all of the usable commands available
+// to btcctl.
+func listCommands() {
+	const (
+		categoryChain int = iota
+		categoryWallet
+		numCategories
+	)
+
+	// Get a list of registered commands and categorize and filter them.
+	cmdMethods := btcjson.RegisteredCmdMethods()
+	categorized := make([][]string, numCategories)
+	for _, method := range cmdMethods {
+		flags, err := btcjson.MethodUsageFlags(method)
+		if err != nil {
+			// This should never happen since the method was just
+			// returned from the registered methods above.
+			continue
+		}
+
+		// Skip the commands that aren't usable from this utility.
+		if flags&unusableFlags != 0 {
+			continue
+		}
+
+		usage, err := btcjson.MethodUsageText(method)
+		if err != nil {
+			// This should never happen since the method was just
+			// returned from the registered methods above.
+			continue
+		}
+
+		category := categoryChain
+		if flags&btcjson.UFWalletOnly != 0 {
+			category = categoryWallet
+		}
+		categorized[category] = append(categorized[category], usage)
+	}
+
+	// Display the command according to their categories.
+	categoryTitles := make([]string, numCategories)
+	categoryTitles[categoryChain] = "Chain Server Commands:"
+	categoryTitles[categoryWallet] = "Wallet Server Commands:"
+	for category := 0; category < numCategories; category++ {
+		fmt.Println(categoryTitles[category])
+		sort.Strings(categorized[category])
+		for _, usage := range categorized[category] {
+			fmt.Println(usage)
+		}
+		fmt.Println()
+	}
+}
+
+// sendPostRequest sends the marshalled JSON-RPC command using HTTP-POST mode
+// to the server described in the passed config struct.  It returns the
+// result as a raw JSON-encoded byte slice.
+func sendPostRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
+	protocol := "http"
+	if !cfg.NoTls {
+		protocol = "https"
+	}
+	url := protocol + "://" + cfg.RPCServer.String()
+
+	bodyReader := bytes.NewReader(marshalledJSON)
+	httpRequest, err := http.NewRequest("POST", url, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Close = true
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.SetBasicAuth(cfg.RPCUser.String(), cfg.RPCPassword.String())
+
+	// Configure TLS if needed.
+	var tlsConfig *tls.Config
+	if !cfg.NoTls {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: cfg.TlsSkipVerify,
+		}
+		if !cfg.TlsSkipVerify && cfg.RPCCert.String() != "" {
+			pem, err := ioutil.ReadFile(cfg.RPCCert.String())
+			if err != nil {
+				return nil, err
+			}
+			pool := btcjson.NewX509CertPool()
+			pool.AppendCertsFromPEM(pem)
+			tlsConfig.RootCAs = pool
+		}
+	}
+	httpClient := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading json reply: %v", err)
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s", httpResponse.Status)
+	}
+
+	var resp btcjson.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return resp.Result, nil
+}
+
+func main() {
+	_, cfg, args, err := loadConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+	if len(args) < 1 {
+		usage("No command specified")
+		os.Exit(1)
+	}
+
+	// Ensure the specified method identifies a valid registered command and
+	// is one of the usable types.
+	method := args[0]
+	flags, err := btcjson.MethodUsageFlags(method)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unrecognized command '%s'\n", method)
+		fmt.Fprintln(os.Stderr, showHelpMessage)
+		fmt.Fprintln(os.Stderr, listCmdMessage)
+		os.Exit(1)
+	}
+	if flags&unusableFlags != 0 {
+		fmt.Fprintf(os.Stderr, "The '%s' command can only be used via "+
+			"websockets\n", method)
+		os.Exit(1)
+	}
+
+	// Convert remaining command line args to a slice of interface{} so
+	// btcjson.NewCmd can coerce each one to the type the concrete command
+	// struct expects.
+	params := make([]interface{}, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		params = append(params, arg)
+	}
+
+	cmd, err := btcjson.NewCmd(method, params...)
+	if err != nil {
+		if jerr, ok := err.(btcjson.Error); ok {
+			fmt.Fprintf(os.Stderr, "%s command: %v (code: %s)\n",
+				method, err, jerr.ErrorCode)
+			commandUsage(method)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(os.Stderr, "%s command: %v\n", method, err)
+		commandUsage(method)
+		os.Exit(1)
+	}
+
+	marshalledJSON, err := btcjson.MarshalCmd(cmd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	result, err := sendPostRequest(marshalledJSON, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Pretty print the result if it is JSON, otherwise just print the
+	// raw string.
+	strResult := string(result)
+	if strings.HasPrefix(strResult, "{") || strings.HasPrefix(strResult, "[") {
+		var dst bytes.Buffer
+		if err := json.Indent(&dst, result, "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to format result: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(dst.String())
+	} else if strResult != "" {
+		fmt.Println(strResult)
+	}
+}
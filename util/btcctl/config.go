@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"github.com/conformal/btcd/util/btcctl/internal/cfgutil"
+	"github.com/conformal/btcd/util/btcctl/netparams"
 	"github.com/conformal/btcutil"
 	"github.com/conformal/go-flags"
 	"net"
@@ -11,50 +13,70 @@ import (
 )
 
 var (
-	btcdHomeDir           = btcutil.AppDataDir("btcd", false)
-	btcctlHomeDir         = btcutil.AppDataDir("btcctl", false)
-	btcwalletHomeDir      = btcutil.AppDataDir("btcwallet", false)
-	defaultConfigFile     = filepath.Join(btcctlHomeDir, "btcctl.conf")
-	defaultRPCCertFile    = filepath.Join(btcdHomeDir, "rpc.cert")
-	defaultWalletCertFile = filepath.Join(btcwalletHomeDir, "rpc.cert")
+	btcdHomeDir              = btcutil.AppDataDir("btcd", false)
+	btcctlHomeDir            = btcutil.AppDataDir("btcctl", false)
+	btcwalletHomeDir         = btcutil.AppDataDir("btcwallet", false)
+	defaultConfigFile        = filepath.Join(btcctlHomeDir, "btcctl.conf")
+	defaultRPCCertFile       = filepath.Join(btcdHomeDir, "rpc.cert")
+	defaultWalletCertFile    = filepath.Join(btcwalletHomeDir, "rpc.cert")
+	defaultBtcdConfigFile    = filepath.Join(btcdHomeDir, "btcd.conf")
+	defaultBtcwalletConfFile = filepath.Join(btcwalletHomeDir, "btcwallet.conf")
 )
 
+// peerConfig holds the subset of a btcd.conf or btcwallet.conf that btcctl
+// cares about when trying to discover RPC credentials the user hasn't
+// supplied directly.
+type peerConfig struct {
+	RPCUser   string `long:"rpcuser"`
+	RPCPass   string `long:"rpcpass"`
+	RPCCert   string `long:"rpccert"`
+	RPCListen string `long:"rpclisten"`
+}
+
+// parsePeerConfig reads the rpcuser, rpcpass, rpccert, and rpclisten options
+// out of the given btcd.conf or btcwallet.conf file.
+func parsePeerConfig(configFile string) (*peerConfig, error) {
+	var pCfg peerConfig
+	parser := flags.NewParser(&pCfg, flags.IgnoreUnknown)
+	if err := flags.NewIniParser(parser).ParseFile(configFile); err != nil {
+		return nil, err
+	}
+	return &pCfg, nil
+}
+
 // config defines the configuration options for btcctl.
 //
 // See loadConfig for details on the configuration load process.
 type config struct {
-	ShowVersion   bool   `short:"V" long:"version" description:"Display version information and exit"`
-	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
-	RPCUser       string `short:"u" long:"rpcuser" description:"RPC username"`
-	RPCPassword   string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
-	RPCServer     string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
-	RPCCert       string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
-	NoTls         bool   `long:"notls" description:"Disable TLS"`
-	TestNet3      bool   `long:"testnet" description:"Connect to testnet"`
-	TlsSkipVerify bool   `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
-	Wallet        bool   `long:"wallet" description:"Connect to wallet"`
+	ShowVersion    bool                   `short:"V" long:"version" description:"Display version information and exit"`
+	ListCommands   bool                   `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
+	ConfigFile     string                 `short:"C" long:"configfile" description:"Path to configuration file"`
+	RPCUser        cfgutil.ExplicitString `short:"u" long:"rpcuser" description:"RPC username"`
+	RPCPassword    cfgutil.ExplicitString `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCServer      cfgutil.ExplicitString `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	RPCCert        cfgutil.ExplicitString `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
+	NoTls          bool                   `long:"notls" description:"Disable TLS"`
+	TestNet3       bool                   `long:"testnet" description:"Connect to testnet"`
+	SimNet         bool                   `long:"simnet" description:"Connect to the simulation test network"`
+	RegressionTest bool                   `long:"regtest" description:"Connect to the regression test network"`
+	TlsSkipVerify  bool                   `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
+	Wallet         bool                   `long:"wallet" description:"Connect to wallet"`
 }
 
-// normalizeAddress returns addr with the passed default port appended if
-// there is not already a port specified.
-func normalizeAddress(addr string, useTestNet3, useWallet bool) string {
+// activeNet is the network btcctl is configured to talk to.  It is selected
+// in loadConfig based on which (if any) of the network flags were
+// specified, and defaults to the main network.
+var activeNet = &netparams.MainNetParams
+
+// normalizeAddress returns addr with the default port for the active
+// network appended if there is not already a port specified.
+func normalizeAddress(addr string, activeNet *netparams.Params, useWallet bool) string {
 	_, _, err := net.SplitHostPort(addr)
 	if err != nil {
-		var defaultPort string
-		if useTestNet3 {
-			if useWallet {
-				defaultPort = "18332"
-			} else {
-				defaultPort = "18334"
-			}
-		} else {
-			if useWallet {
-				defaultPort = "8332"
-			} else {
-				defaultPort = "8334"
-			}
+		defaultPort := activeNet.RPCClientPort
+		if useWallet {
+			defaultPort = activeNet.RPCWalletPort
 		}
-
 		return net.JoinHostPort(addr, defaultPort)
 	}
 	return addr
@@ -78,10 +100,10 @@ func cleanAndExpandPath(path string) string {
 // line options.
 //
 // The configuration proceeds as follows:
-// 	1) Start with a default config with sane settings
-// 	2) Pre-parse the command line to check for an alternative config file
-// 	3) Load configuration file overwriting defaults with any specified options
-// 	4) Parse CLI options and overwrite/add any specified options
+//  1. Start with a default config with sane settings
+//  2. Pre-parse the command line to check for an alternative config file
+//  3. Load configuration file overwriting defaults with any specified options
+//  4. Parse CLI options and overwrite/add any specified options
 //
 // The above results in functioning properly without any config settings
 // while still allowing the user to override settings with config files and
@@ -114,6 +136,13 @@ func loadConfig() (*flags.Parser, *config, []string, error) {
 		os.Exit(0)
 	}
 
+	// Show the available commands and exit if the list commands flag was
+	// specified.
+	if preCfg.ListCommands {
+		listCommands()
+		os.Exit(0)
+	}
+
 	// Load additional config from file.
 	parser := flags.NewParser(&cfg, flags.PassDoubleDash|flags.HelpFlag)
 	err = flags.NewIniParser(parser).ParseFile(preCfg.ConfigFile)
@@ -130,25 +159,94 @@ func loadConfig() (*flags.Parser, *config, []string, error) {
 		return parser, nil, nil, err
 	}
 
-	// Choose a default RPC certificate file if the user did not
-	// specify one.
-	if cfg.RPCCert == "" {
+	// Multiple networks can't be selected simultaneously.  Pick the active
+	// one (mainnet if none were specified) so the rest of loadConfig has a
+	// single source of truth for network-specific defaults such as ports.
+	numNets := 0
+	if cfg.TestNet3 {
+		activeNet = &netparams.TestNet3Params
+		numNets++
+	}
+	if cfg.SimNet {
+		activeNet = &netparams.SimNetParams
+		numNets++
+	}
+	if cfg.RegressionTest {
+		activeNet = &netparams.RegressionNetParams
+		numNets++
+	}
+	if numNets > 1 {
+		str := "%s: the testnet, simnet, and regtest params can't be " +
+			"used together -- choose one"
+		err := fmt.Errorf(str, "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		return parser, nil, nil, err
+	}
+
+	// Connect to localhost if the user did not specify a server.
+	if cfg.RPCServer.String() == "" {
+		cfg.RPCServer = *cfgutil.NewExplicitString("localhost")
+	}
+
+	// When the RPC user, password, or certificate were not supplied on the
+	// command line or in btcctl.conf, try to pick them up from the peer's
+	// own configuration file so the user doesn't have to duplicate the
+	// same credentials in three places.  btcd.conf is consulted unless
+	// --wallet was given, in which case btcwallet.conf is used instead.
+	if !cfg.RPCUser.ExplicitlySet() || !cfg.RPCPassword.ExplicitlySet() ||
+		!cfg.RPCCert.ExplicitlySet() {
+
+		peerConfigFile := defaultBtcdConfigFile
 		if cfg.Wallet {
-			cfg.RPCCert = defaultWalletCertFile
-		} else {
-			cfg.RPCCert = defaultRPCCertFile
+			peerConfigFile = defaultBtcwalletConfFile
+		}
+		if pCfg, err := parsePeerConfig(peerConfigFile); err == nil {
+			if !cfg.RPCUser.ExplicitlySet() && pCfg.RPCUser != "" {
+				cfg.RPCUser.UnmarshalFlag(pCfg.RPCUser)
+			}
+			if !cfg.RPCPassword.ExplicitlySet() && pCfg.RPCPass != "" {
+				cfg.RPCPassword.UnmarshalFlag(pCfg.RPCPass)
+			}
+			if !cfg.RPCCert.ExplicitlySet() && pCfg.RPCCert != "" {
+				cfg.RPCCert.UnmarshalFlag(pCfg.RPCCert)
+			}
 		}
 	}
 
-	// Handle environment variable expansion in the RPC certificate path.
-	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
-
-	// Connect to localhost if the user did not specify a server.
-	if cfg.RPCServer == "" {
-		cfg.RPCServer = "localhost"
+	// Choose a default RPC certificate file if one still hasn't been
+	// determined.  When running against the wallet, prefer the btcwallet
+	// cert, but fall back to the btcd cert if the wallet hasn't generated
+	// one yet and the server being contacted is on localhost, and vice
+	// versa.
+	if !cfg.RPCCert.ExplicitlySet() {
+		rpcCert := defaultRPCCertFile
+		fallbackCert := defaultWalletCertFile
+		if cfg.Wallet {
+			rpcCert, fallbackCert = defaultWalletCertFile, defaultRPCCertFile
+		}
+		if _, err := os.Stat(rpcCert); os.IsNotExist(err) {
+			if _, err := os.Stat(fallbackCert); err == nil && isLocalhost(cfg.RPCServer.String()) {
+				rpcCert = fallbackCert
+			}
+		}
+		cfg.RPCCert = *cfgutil.NewExplicitString(rpcCert)
 	}
 
-	cfg.RPCServer = normalizeAddress(cfg.RPCServer, cfg.TestNet3, cfg.Wallet)
+	// Handle environment variable expansion in the RPC certificate path.
+	cfg.RPCCert = *cfgutil.NewExplicitString(cleanAndExpandPath(cfg.RPCCert.String()))
+
+	cfg.RPCServer = *cfgutil.NewExplicitString(
+		normalizeAddress(cfg.RPCServer.String(), activeNet, cfg.Wallet))
 
 	return parser, &cfg, remainingArgs, nil
 }
+
+// isLocalhost returns whether the host portion of addr refers to the local
+// machine.
+func isLocalhost(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package cfgutil provides types useful for working with configuration
+// options defined via go-flags.
+package cfgutil
+
+import "strconv"
+
+// ExplicitString is a string that distinguishes whether or not a flag was
+// explicitly set.  When a flag is explicitly set, the value is used for the
+// string's value, otherwise the default value is used.
+type ExplicitString struct {
+	value         string
+	explicitlySet bool
+}
+
+// NewExplicitString creates a new ExplicitString with a default value, and
+// a false explicitlySet value.
+func NewExplicitString(def string) *ExplicitString {
+	return &ExplicitString{value: def}
+}
+
+// String returns the string value, regardless of whether it was explicitly
+// set or left as the default.
+func (e *ExplicitString) String() string {
+	return e.value
+}
+
+// ExplicitlySet returns whether the flag was explicitly set either on the
+// command line or in an ini file rather than left at its default value.
+func (e *ExplicitString) ExplicitlySet() bool {
+	return e.explicitlySet
+}
+
+// MarshalFlag satisifes the flags.Marshaler interface.
+func (e *ExplicitString) MarshalFlag() (string, error) {
+	return e.value, nil
+}
+
+// UnmarshalFlag satisifes the flags.Unmarshaler interface.
+func (e *ExplicitString) UnmarshalFlag(value string) error {
+	e.value = value
+	e.explicitlySet = true
+	return nil
+}
+
+// ExplicitBool is a boolean that distinguishes whether or not a flag was
+// explicitly set.  When a flag is explicitly set, the value is used for the
+// bool's value, otherwise the default value is used.
+type ExplicitBool struct {
+	value         bool
+	explicitlySet bool
+}
+
+// NewExplicitBool creates a new ExplicitBool with a default value, and a
+// false explicitlySet value.
+func NewExplicitBool(def bool) *ExplicitBool {
+	return &ExplicitBool{value: def}
+}
+
+// Bool returns the bool value, regardless of whether it was explicitly set
+// or left as the default.
+func (e *ExplicitBool) Bool() bool {
+	return e.value
+}
+
+// ExplicitlySet returns whether the flag was explicitly set either on the
+// command line or in an ini file rather than left at its default value.
+func (e *ExplicitBool) ExplicitlySet() bool {
+	return e.explicitlySet
+}
+
+// MarshalFlag satisifes the flags.Marshaler interface.
+func (e *ExplicitBool) MarshalFlag() (string, error) {
+	if e.value {
+		return "1", nil
+	}
+	return "0", nil
+}
+
+// UnmarshalFlag satisifes the flags.Unmarshaler interface.
+func (e *ExplicitBool) UnmarshalFlag(value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	e.value = b
+	e.explicitlySet = true
+	return nil
+}
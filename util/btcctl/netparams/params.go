@@ -0,0 +1,52 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package netparams provides the RPC ports used by btcd and btcwallet on
+// each network btcctl knows how to talk to.  Keeping them in a lookup table
+// rather than scattered if/else chains means adding a new network is a
+// single new Params value instead of a change everywhere a port is picked.
+package netparams
+
+// Params defines the RPC ports used by btcd and btcwallet for a particular
+// network.
+type Params struct {
+	// Name is the name of the network.
+	Name string
+
+	// RPCClientPort is the default port used by btcd's RPC server.
+	RPCClientPort string
+
+	// RPCWalletPort is the default port used by btcwallet's RPC server.
+	RPCWalletPort string
+}
+
+// MainNetParams contains parameters specific to the main network.
+var MainNetParams = Params{
+	Name:          "mainnet",
+	RPCClientPort: "8334",
+	RPCWalletPort: "8332",
+}
+
+// TestNet3Params contains parameters specific to the test network (version
+// 3).
+var TestNet3Params = Params{
+	Name:          "testnet",
+	RPCClientPort: "18334",
+	RPCWalletPort: "18332",
+}
+
+// SimNetParams contains parameters specific to the simulation test network.
+var SimNetParams = Params{
+	Name:          "simnet",
+	RPCClientPort: "18556",
+	RPCWalletPort: "18554",
+}
+
+// RegressionNetParams contains parameters specific to the regression test
+// network.
+var RegressionNetParams = Params{
+	Name:          "regtest",
+	RPCClientPort: "18334",
+	RPCWalletPort: "18332",
+}